@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Isaiah-Turner/project-viewer/internal/queries"
+)
+
+// NewCandlesHandler returns an http.Handler for GET /candles, serving OHLC candlestick and
+// base/counter volume data for a source/dest corridor through the given QueryBackend. It reads
+// source_code, source_issuer, dest_code, dest_issuer, start, end, and bucket from the query string
+// and writes the VolumeSeries results as JSON. Dispatching through queries.QueryBackend rather
+// than a concrete client lets operators point this handler at either BigQuery or a
+// Postgres/TimescaleDB replica, matching NewRatesHandler.
+func NewCandlesHandler(backend queries.QueryBackend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		source := queries.Asset{Code: q.Get("source_code"), Issuer: q.Get("source_issuer")}
+		dest := queries.Asset{Code: q.Get("dest_code"), Issuer: q.Get("dest_issuer")}
+
+		bucket := q.Get("bucket")
+		if bucket == "" {
+			bucket = "ledger"
+		}
+
+		results, err := backend.VolumeSeries(r.Context(), source, dest, q.Get("start"), q.Get("end"), bucket)
+		if err != nil {
+			log.Printf("candles query failed: %v", err)
+			http.Error(w, "failed to run candles query", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			log.Printf("candles response encoding failed: %v", err)
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Isaiah-Turner/project-viewer/internal/queries"
+)
+
+// TestCandlesHandlerServesBackendResults verifies the handler dispatches through QueryBackend and
+// serializes whatever the backend returns.
+func TestCandlesHandlerServesBackendResults(t *testing.T) {
+	backend := &stubBackend{volumeResults: []queries.CandleResult{{Title: "Ledger 1", Open: 1.5}}}
+	handler := NewCandlesHandler(backend)
+
+	req := httptest.NewRequest(http.MethodGet, "/candles?source_code=USD&dest_code=EUR", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Ledger 1") {
+		t.Errorf("expected response to contain the backend's result, got: %s", rec.Body.String())
+	}
+}
+
+// TestCandlesHandlerHidesBackendErrors verifies a backend error is logged server-side, not
+// returned to the client verbatim, matching the fix applied to top_traders.go.
+func TestCandlesHandlerHidesBackendErrors(t *testing.T) {
+	backend := &stubBackend{volumeErr: errors.New("SELECT * FROM secret_table WHERE leaked=true")}
+	handler := NewCandlesHandler(backend)
+
+	req := httptest.NewRequest(http.MethodGet, "/candles?source_code=USD&dest_code=EUR", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "secret_table") {
+		t.Errorf("expected backend error details to be hidden from the client, got: %s", rec.Body.String())
+	}
+}
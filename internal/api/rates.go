@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/Isaiah-Turner/project-viewer/internal/queries"
+)
+
+// NewRatesHandler returns an http.Handler for GET /rates, serving the effective rate series for a
+// source/dest corridor through the given QueryBackend, net of fees. It reads source_code,
+// source_issuer, dest_code, dest_issuer, start, end, and bucket from the query string and writes
+// the RateSeries results as JSON. Dispatching through queries.QueryBackend rather than a concrete
+// client lets operators point this handler at either BigQuery or a Postgres/TimescaleDB replica.
+func NewRatesHandler(backend queries.QueryBackend, fees queries.FeeSchedule) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		source := queries.Asset{Code: q.Get("source_code"), Issuer: q.Get("source_issuer")}
+		dest := queries.Asset{Code: q.Get("dest_code"), Issuer: q.Get("dest_issuer")}
+
+		bucket := q.Get("bucket")
+		if bucket == "" {
+			bucket = "ledger"
+		}
+
+		path := []queries.Asset{source, dest}
+		results, err := backend.RateSeries(r.Context(), path, q.Get("start"), q.Get("end"), bucket, fees)
+		if err != nil {
+			log.Printf("rates query failed: %v", err)
+			http.Error(w, "failed to run rates query", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			log.Printf("rates response encoding failed: %v", err)
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Isaiah-Turner/project-viewer/internal/queries"
+)
+
+// stubBackend is a queries.QueryBackend whose RateSeries/VolumeSeries return canned results, used
+// to verify NewRatesHandler dispatches through the interface rather than a concrete client.
+type stubBackend struct {
+	rateResults        []queries.RateResult
+	rateErr            error
+	volumeResults      []queries.CandleResult
+	volumeErr          error
+	topParticipants    []queries.TopParticipantResult
+	topParticipantsErr error
+}
+
+func (b *stubBackend) RateSeries(ctx context.Context, path []queries.Asset, start, end, bucket string, fees queries.FeeSchedule) ([]queries.RateResult, error) {
+	return b.rateResults, b.rateErr
+}
+
+func (b *stubBackend) VolumeSeries(ctx context.Context, source, dest queries.Asset, start, end, bucket string) ([]queries.CandleResult, error) {
+	return b.volumeResults, b.volumeErr
+}
+
+func (b *stubBackend) TopParticipants(ctx context.Context, source, dest queries.Asset, start, end string, limit int) ([]queries.TopParticipantResult, error) {
+	return b.topParticipants, b.topParticipantsErr
+}
+
+// TestRatesHandlerServesBackendResults verifies the handler dispatches through QueryBackend and
+// serializes whatever the backend returns.
+func TestRatesHandlerServesBackendResults(t *testing.T) {
+	backend := &stubBackend{rateResults: []queries.RateResult{{Title: "Ledger 1", Rate: 1.5}}}
+	handler := NewRatesHandler(backend, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/rates?source_code=USD&dest_code=EUR", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Ledger 1") {
+		t.Errorf("expected response to contain the backend's result, got: %s", rec.Body.String())
+	}
+}
+
+// TestRatesHandlerHidesBackendErrors verifies a backend error is logged server-side, not returned
+// to the client verbatim, matching the fix applied to top_traders.go.
+func TestRatesHandlerHidesBackendErrors(t *testing.T) {
+	backend := &stubBackend{rateErr: errors.New("SELECT * FROM secret_table WHERE leaked=true")}
+	handler := NewRatesHandler(backend, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/rates?source_code=USD&dest_code=EUR", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "secret_table") {
+		t.Errorf("expected backend error details to be hidden from the client, got: %s", rec.Body.String())
+	}
+}
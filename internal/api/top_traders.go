@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Isaiah-Turner/project-viewer/internal/queries"
+)
+
+// NewTopTradersHandler returns an http.Handler for GET /top-traders, serving the top accounts by
+// traded volume on a source/dest corridor within an optional time range through the given
+// QueryBackend. It reads source_code, source_issuer, dest_code, dest_issuer, start, end, and limit
+// from the query string and writes the TopParticipants results as JSON. Dispatching through
+// queries.QueryBackend rather than a concrete client lets operators point this handler at either
+// BigQuery or a Postgres/TimescaleDB replica, matching NewRatesHandler.
+func NewTopTradersHandler(backend queries.QueryBackend) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		source := queries.Asset{Code: q.Get("source_code"), Issuer: q.Get("source_issuer")}
+		dest := queries.Asset{Code: q.Get("dest_code"), Issuer: q.Get("dest_issuer")}
+
+		limit := 20
+		if raw := q.Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+
+		results, err := backend.TopParticipants(r.Context(), source, dest, q.Get("start"), q.Get("end"), limit)
+		if err != nil {
+			log.Printf("top-traders query failed: %v", err)
+			http.Error(w, "failed to run top traders query", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			log.Printf("top-traders response encoding failed: %v", err)
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+	})
+}
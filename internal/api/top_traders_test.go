@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Isaiah-Turner/project-viewer/internal/queries"
+)
+
+// TestTopTradersHandlerRejectsInvalidLimit verifies that a non-numeric limit query parameter is
+// rejected before any query is run, rather than falling through to a BigQuery error.
+func TestTopTradersHandlerRejectsInvalidLimit(t *testing.T) {
+	handler := NewTopTradersHandler(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/top-traders?limit=notanumber", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an invalid limit, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+// TestTopTradersHandlerServesBackendResults verifies the handler dispatches through QueryBackend
+// (so it can run against either BigQuery or a Postgres/TimescaleDB replica) rather than a
+// concrete BigQuery client.
+func TestTopTradersHandlerServesBackendResults(t *testing.T) {
+	backend := &stubBackend{topParticipants: []queries.TopParticipantResult{{Account: "GABC", RawBaseVolume: 100}}}
+	handler := NewTopTradersHandler(backend)
+
+	req := httptest.NewRequest(http.MethodGet, "/top-traders?source_code=USD&dest_code=EUR", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "GABC") {
+		t.Errorf("expected response to contain the backend's result, got: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,22 @@
+package queries
+
+import "context"
+
+// QueryBackend abstracts the datastore that rate and volume series are computed against, so that
+// callers are not tied to a BigQuery client. This lets operators point the viewer at a local
+// Horizon replica instead of paying for BigQuery quota.
+type QueryBackend interface {
+	// RateSeries returns the effective rate across path (source, any intermediates, dest, as
+	// RunRateQuery expects), bucketed by bucket, for ledgers closed between start and end (Unix
+	// timestamps as strings, both empty meaning no lower/upper bound), net of fees. A backend that
+	// cannot synthesize a multi-hop path should return an error rather than silently dropping hops.
+	RateSeries(ctx context.Context, path []Asset, start, end, bucket string, fees FeeSchedule) ([]RateResult, error)
+
+	// VolumeSeries returns OHLC candlestick and base/counter volume data between source and dest,
+	// bucketed by bucket, for ledgers closed between start and end.
+	VolumeSeries(ctx context.Context, source, dest Asset, start, end, bucket string) ([]CandleResult, error)
+
+	// TopParticipants returns the top limit accounts by traded volume on the source/dest corridor,
+	// for ledgers closed between start and end.
+	TopParticipants(ctx context.Context, source, dest Asset, start, end string, limit int) ([]TopParticipantResult, error)
+}
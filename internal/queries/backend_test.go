@@ -0,0 +1,9 @@
+package queries
+
+// Compile-time assertions that both backends satisfy QueryBackend, so a signature drift between
+// a backend's methods and the interface fails the build instead of surfacing only at a
+// call site that happens to use one concrete backend.
+var (
+	_ QueryBackend = (*BigQueryBackend)(nil)
+	_ QueryBackend = (*PostgresBackend)(nil)
+)
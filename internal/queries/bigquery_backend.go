@@ -0,0 +1,40 @@
+package queries
+
+import (
+	"context"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// BigQueryBackend implements QueryBackend against the crypto-stellar and hubble-261722 BigQuery
+// datasets, using the same queries RunRateQuery and RunCandleQuery have always run. If Cache is
+// non-nil, RateSeries is served through it so repeated dashboard reloads for the same corridor
+// don't re-bill BigQuery.
+type BigQueryBackend struct {
+	Client *bigquery.Client
+	Cache  *RateCache
+}
+
+// NewBigQueryBackend returns a QueryBackend backed by the given BigQuery client, caching
+// RateSeries results in cache. Pass a nil cache to run every RateSeries call uncached.
+func NewBigQueryBackend(client *bigquery.Client, cache *RateCache) *BigQueryBackend {
+	return &BigQueryBackend{Client: client, Cache: cache}
+}
+
+// RateSeries implements QueryBackend.
+func (b *BigQueryBackend) RateSeries(ctx context.Context, path []Asset, start, end, bucket string, fees FeeSchedule) ([]RateResult, error) {
+	if b.Cache != nil {
+		return RunCachedRateQuery(ctx, b.Cache, path, start, end, bucket, fees, b.Client)
+	}
+	return RunRateQuery(ctx, path, start, end, bucket, fees, b.Client)
+}
+
+// VolumeSeries implements QueryBackend.
+func (b *BigQueryBackend) VolumeSeries(ctx context.Context, source, dest Asset, start, end, bucket string) ([]CandleResult, error) {
+	return RunCandleQuery(ctx, source, dest, start, end, bucket, b.Client)
+}
+
+// TopParticipants implements QueryBackend.
+func (b *BigQueryBackend) TopParticipants(ctx context.Context, source, dest Asset, start, end string, limit int) ([]TopParticipantResult, error) {
+	return RunTopParticipantsQuery(ctx, source, dest, start, end, limit, b.Client)
+}
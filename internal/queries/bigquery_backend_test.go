@@ -0,0 +1,25 @@
+package queries
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBigQueryBackendRateSeriesServesFromCache verifies RateSeries consults Cache before hitting
+// BigQuery, so repeated dashboard reloads for the same corridor don't re-bill BigQuery. The
+// backend's Client is left nil: if RateSeries fell through to RunRateQuery on a cache hit, it
+// would panic dereferencing the client and fail the test.
+func TestBigQueryBackendRateSeriesServesFromCache(t *testing.T) {
+	cache := NewRateCache(2, time.Minute)
+	want := []RateResult{{Title: "Ledger 1", Rate: 1.5}}
+	cache.set(rateCacheKey(testPath, "", "", "ledger", nil), want)
+
+	backend := &BigQueryBackend{Cache: cache}
+	got, err := backend.RateSeries(nil, testPath, "", "", "ledger", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != want[0] {
+		t.Errorf("expected cached results %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,121 @@
+package queries
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// RateCache is an in-process, size-bounded LRU cache of RunRateQuery results, keyed by the
+// canonicalized corridor/bucket/fee parameters. Entries expire after ttl so that repeated
+// dashboard reloads for the same corridor don't re-bill BigQuery, while still picking up new
+// trades after a bounded delay.
+type RateCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type rateCacheEntry struct {
+	key       string
+	results   []RateResult
+	expiresAt time.Time
+}
+
+// NewRateCache returns an empty RateCache holding at most capacity entries, each valid for ttl.
+func NewRateCache(capacity int, ttl time.Duration) *RateCache {
+	return &RateCache{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// rateCacheKey canonicalizes the parameters of a rate query into a single cache key.
+func rateCacheKey(path []Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, fees FeeSchedule) string {
+	issuers := make([]string, 0, len(fees))
+	for issuer := range fees {
+		issuers = append(issuers, issuer)
+	}
+	sort.Strings(issuers)
+
+	key := ""
+	for _, asset := range path {
+		key += fmt.Sprintf("%s:%s>", asset.Code, asset.Issuer)
+	}
+	key += fmt.Sprintf("|%s|%s|%s", startUnixTimestamp, endUnixTimestamp, aggregateBy)
+	for _, issuer := range issuers {
+		key += fmt.Sprintf("|%s=%d", issuer, fees[issuer])
+	}
+	return key
+}
+
+// get returns the cached results for key, if present and not yet expired.
+func (c *RateCache) get(key string) ([]RateResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*rateCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.results, true
+}
+
+// set stores results under key, evicting the least recently used entry if the cache is full.
+func (c *RateCache) set(key string, results []RateResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		elem.Value.(*rateCacheEntry).results = results
+		elem.Value.(*rateCacheEntry).expiresAt = time.Now().Add(c.ttl)
+		return
+	}
+
+	elem := c.order.PushFront(&rateCacheEntry{key: key, results: results, expiresAt: time.Now().Add(c.ttl)})
+	c.items[key] = elem
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*rateCacheEntry).key)
+		}
+	}
+}
+
+// RunCachedRateQuery wraps RunRateQuery with cache, serving a cached result when one exists and
+// hasn't expired, and populating cache on a miss.
+func RunCachedRateQuery(ctx context.Context, cache *RateCache, path []Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, fees FeeSchedule, client *bigquery.Client) ([]RateResult, error) {
+	key := rateCacheKey(path, startUnixTimestamp, endUnixTimestamp, aggregateBy, fees)
+	if results, ok := cache.get(key); ok {
+		return results, nil
+	}
+
+	results, err := RunRateQuery(ctx, path, startUnixTimestamp, endUnixTimestamp, aggregateBy, fees, client)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.set(key, results)
+	return results, nil
+}
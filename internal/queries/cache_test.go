@@ -0,0 +1,59 @@
+package queries
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateCacheGetSet(t *testing.T) {
+	cache := NewRateCache(2, time.Minute)
+	key := rateCacheKey(testPath, "", "", "ledger", nil)
+
+	if _, ok := cache.get(key); ok {
+		t.Fatalf("expected empty cache to miss")
+	}
+
+	want := []RateResult{{Title: "Ledger 1", Rate: 1.5}}
+	cache.set(key, want)
+
+	got, ok := cache.get(key)
+	if !ok || len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("expected cache hit with %v, got %v (ok=%v)", want, got, ok)
+	}
+}
+
+func TestRateCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewRateCache(2, time.Minute)
+	keyA := rateCacheKey(testPath, "1", "2", "ledger", nil)
+	keyB := rateCacheKey(testPath, "3", "4", "ledger", nil)
+	keyC := rateCacheKey(testPath, "5", "6", "ledger", nil)
+
+	cache.set(keyA, []RateResult{{Title: "a"}})
+	cache.set(keyB, []RateResult{{Title: "b"}})
+
+	// touch keyA so keyB becomes the least recently used entry
+	cache.get(keyA)
+	cache.set(keyC, []RateResult{{Title: "c"}})
+
+	if _, ok := cache.get(keyB); ok {
+		t.Errorf("expected keyB to have been evicted")
+	}
+	if _, ok := cache.get(keyA); !ok {
+		t.Errorf("expected keyA to remain cached")
+	}
+	if _, ok := cache.get(keyC); !ok {
+		t.Errorf("expected keyC to remain cached")
+	}
+}
+
+func TestRateCacheExpiresEntries(t *testing.T) {
+	cache := NewRateCache(2, time.Millisecond)
+	key := rateCacheKey(testPath, "", "", "ledger", nil)
+	cache.set(key, []RateResult{{Title: "a"}})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := cache.get(key); ok {
+		t.Errorf("expected expired entry to miss")
+	}
+}
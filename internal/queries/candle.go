@@ -0,0 +1,112 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// CandleResult holds a single OHLC candlestick bucket for a source->dest corridor, along with the
+// base and counter volume traded within that bucket.
+type CandleResult struct {
+	Title         string  `bigquery:"title"`
+	Open          float64 `bigquery:"open"`
+	High          float64 `bigquery:"high"`
+	Low           float64 `bigquery:"low"`
+	Close         float64 `bigquery:"close"`
+	BaseVolume    float64 `bigquery:"base_volume"`
+	CounterVolume float64 `bigquery:"counter_volume"`
+}
+
+// RunCandleQuery queries BigQuery for OHLC candlestick and volume data for the specified corridor and returns the results
+func RunCandleQuery(ctx context.Context, source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, client *bigquery.Client) ([]CandleResult, error) {
+	query := createCandleQuery(source, dest, startUnixTimestamp, endUnixTimestamp, aggregateBy)
+	it, err := runParameterizedQuery(ctx, query, nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("error running query \n%s\n%v", query, err)
+	}
+
+	var results []CandleResult
+	for {
+		var res CandleResult
+		if err := it.Next(&res); err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing results from query: %v", err)
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// createCandleQuery returns a query that gets Open/High/Low/Close prices and base/counter volume
+// between two assets, grouped by the requested bucket. The prices and volumes are calculated by
+// looking at trades involving the assets within the timestamp range. The timestamps are in UTC
+// to ensure they are consistent with the ledger closed_at timestamps.
+func createCandleQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string) string {
+	// A sample query is below:
+	// WITH candles AS (
+	// 		SELECT FORMAT("Ledger %d", L.sequence) AS title, B.asset_code AS base_asset_code, B.asset_issuer AS base_asset_issuer, C.asset_code AS counter_asset_code, C.asset_issuer AS counter_asset_issuer,
+	// 		ARRAY_AGG(T.counter_amount/T.base_amount ORDER BY L.closed_at ASC LIMIT 1)[OFFSET(0)] AS openPrice,
+	// 		ARRAY_AGG(T.counter_amount/T.base_amount ORDER BY L.closed_at DESC LIMIT 1)[OFFSET(0)] AS closePrice,
+	// 		MIN(T.counter_amount/T.base_amount) AS lowPrice,
+	// 		MAX(T.counter_amount/T.base_amount) AS highPrice,
+	// 		SUM(T.base_amount) AS baseVolume,
+	// 		SUM(T.counter_amount) AS counterVolume,
+	// 		FROM `crypto-stellar.crypto_stellar.history_trades` T
+	// 		JOIN `crypto-stellar.crypto_stellar.history_assets` B ON B.id=T.base_asset_id
+	// 		JOIN `crypto-stellar.crypto_stellar.history_assets` C ON C.id=T.counter_asset_id
+	// 		JOIN `crypto-stellar.crypto_stellar.history_ledgers` L ON L.closed_at=T.ledger_closed_at
+	// 		WHERE ((B.asset_code="NGNT" AND B.asset_issuer="GAWODAROMJ33V5YDFY3NPYTHVYQG7MJXVJ2ND3AOGIHYRWINES6ACCPD" AND C.asset_code="EURT" AND C.asset_issuer="GAP5LETOV6YIE62YAM56STDANPRDO7ZFDBGSNHJQIYGGKSMOZAHOOS2S")
+	// 		OR (C.asset_code="NGNT" AND C.asset_issuer="GAWODAROMJ33V5YDFY3NPYTHVYQG7MJXVJ2ND3AOGIHYRWINES6ACCPD" AND B.asset_code="EURT" AND B.asset_issuer="GAP5LETOV6YIE62YAM56STDANPRDO7ZFDBGSNHJQIYGGKSMOZAHOOS2S"))
+	// 		GROUP BY title, B.asset_code, B.asset_issuer, C.asset_code, C.asset_issuer
+	// )
+	// SELECT candles.title, CASE WHEN candles.base_asset_code="NGNT" AND candles.base_asset_issuer="GAWODAROMJ33V5YDFY3NPYTHVYQG7MJXVJ2ND3AOGIHYRWINES6ACCPD"
+	// THEN candles.openPrice ELSE 1/candles.openPrice END AS open, ...
+	// FROM candles ORDER BY candles.title ASC LIMIT 100
+
+	baseAssetMatch := fmt.Sprintf("(B.asset_code=\"%s\" AND B.asset_issuer=\"%s\" AND C.asset_code=\"%s\" AND C.asset_issuer=\"%s\")",
+		source.Code, source.Issuer, dest.Code, dest.Issuer)
+	counterAssetMatch := fmt.Sprintf("(C.asset_code=\"%s\" AND C.asset_issuer=\"%s\" AND B.asset_code=\"%s\" AND B.asset_issuer=\"%s\")",
+		source.Code, source.Issuer, dest.Code, dest.Issuer)
+	titleField := getTitleField("L.sequence", "L.closed_at", aggregateBy)
+
+	query := "WITH candles AS ("
+	query += fmt.Sprintf(" SELECT %s, B.asset_code AS base_asset_code, B.asset_issuer AS base_asset_issuer, C.asset_code AS counter_asset_code, C.asset_issuer AS counter_asset_issuer,", titleField)
+	query += ` ARRAY_AGG(T.counter_amount/T.base_amount ORDER BY L.closed_at ASC LIMIT 1)[OFFSET(0)] AS openPrice,`
+	query += ` ARRAY_AGG(T.counter_amount/T.base_amount ORDER BY L.closed_at DESC LIMIT 1)[OFFSET(0)] AS closePrice,`
+	query += ` MIN(T.counter_amount/T.base_amount) AS lowPrice,`
+	query += ` MAX(T.counter_amount/T.base_amount) AS highPrice,`
+	query += ` SUM(T.base_amount) AS baseVolume,`
+	query += ` SUM(T.counter_amount) AS counterVolume,`
+	query += " FROM `crypto-stellar.crypto_stellar.history_trades` T"
+	query += " JOIN `crypto-stellar.crypto_stellar.history_assets` B ON B.id=T.base_asset_id"
+	query += " JOIN `crypto-stellar.crypto_stellar.history_assets` C ON C.id=T.counter_asset_id"
+	query += " JOIN `crypto-stellar.crypto_stellar.history_ledgers` L ON L.closed_at=T.ledger_closed_at"
+	query += fmt.Sprintf(" WHERE (%s OR %s)", baseAssetMatch, counterAssetMatch)
+
+	if startUnixTimestamp != "" && endUnixTimestamp != "" {
+		query += fmt.Sprintf(" AND L.closed_at BETWEEN TIMESTAMP_SECONDS(%s) AND TIMESTAMP_SECONDS(%s)", startUnixTimestamp, endUnixTimestamp)
+	}
+
+	query += " GROUP BY title, B.asset_code, B.asset_issuer, C.asset_code, C.asset_issuer)"
+
+	// if the base is not the source asset, then the trade prices are quoted in the reversed
+	// direction and must be inverted (and high/low and base/counter volumes swapped) to express
+	// the candle in source->dest terms
+	baseIsSource := fmt.Sprintf("candles.base_asset_code=\"%s\" AND candles.base_asset_issuer=\"%s\"", source.Code, source.Issuer)
+
+	query += fmt.Sprintf(" SELECT candles.title, CASE WHEN %s THEN candles.openPrice ELSE 1/candles.openPrice END AS open,", baseIsSource)
+	query += fmt.Sprintf(" CASE WHEN %s THEN candles.closePrice ELSE 1/candles.closePrice END AS close,", baseIsSource)
+	query += fmt.Sprintf(" CASE WHEN %s THEN candles.lowPrice ELSE 1/candles.highPrice END AS low,", baseIsSource)
+	query += fmt.Sprintf(" CASE WHEN %s THEN candles.highPrice ELSE 1/candles.lowPrice END AS high,", baseIsSource)
+	query += fmt.Sprintf(" CASE WHEN %s THEN candles.baseVolume ELSE candles.counterVolume END AS base_volume,", baseIsSource)
+	query += fmt.Sprintf(" CASE WHEN %s THEN candles.counterVolume ELSE candles.baseVolume END AS counter_volume", baseIsSource)
+	query += " FROM candles"
+	query += fmt.Sprintf(" ORDER BY candles.title ASC LIMIT %d", queryLimit)
+	return query
+}
@@ -0,0 +1,73 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCreateCandleQueryInvertsOpenCloseOnReversal verifies that open/close prices are inverted via
+// a 1/price CASE WHEN fallback, so a reversed trade row (base=dest, counter=source) is still
+// expressed in source->dest terms.
+func TestCreateCandleQueryInvertsOpenCloseOnReversal(t *testing.T) {
+	query := createCandleQuery(testSource, testDest, "", "", "ledger")
+
+	if !strings.Contains(query, "THEN candles.openPrice ELSE 1/candles.openPrice END AS open") {
+		t.Errorf("expected open price to fall back to its reciprocal on reversal, got query: %s", query)
+	}
+	if !strings.Contains(query, "THEN candles.closePrice ELSE 1/candles.closePrice END AS close") {
+		t.Errorf("expected close price to fall back to its reciprocal on reversal, got query: %s", query)
+	}
+}
+
+// TestCreateCandleQuerySwapsHighLowOnReversal verifies that high and low are not just inverted in
+// place but swapped with each other, since inverting a price flips which extreme is higher.
+func TestCreateCandleQuerySwapsHighLowOnReversal(t *testing.T) {
+	query := createCandleQuery(testSource, testDest, "", "", "ledger")
+
+	if !strings.Contains(query, "THEN candles.lowPrice ELSE 1/candles.highPrice END AS low") {
+		t.Errorf("expected low to fall back to the reciprocal of high on reversal, got query: %s", query)
+	}
+	if !strings.Contains(query, "THEN candles.highPrice ELSE 1/candles.lowPrice END AS high") {
+		t.Errorf("expected high to fall back to the reciprocal of low on reversal, got query: %s", query)
+	}
+}
+
+// TestCreateCandleQuerySwapsBaseCounterVolumeOnReversal verifies that base_volume/counter_volume
+// are swapped (not just passed through) when the trade row's base asset is the dest, not the
+// source, so base_volume always reflects the source asset's traded volume.
+func TestCreateCandleQuerySwapsBaseCounterVolumeOnReversal(t *testing.T) {
+	query := createCandleQuery(testSource, testDest, "", "", "ledger")
+
+	if !strings.Contains(query, "THEN candles.baseVolume ELSE candles.counterVolume END AS base_volume") {
+		t.Errorf("expected base_volume to fall back to counterVolume on reversal, got query: %s", query)
+	}
+	if !strings.Contains(query, "THEN candles.counterVolume ELSE candles.baseVolume END AS counter_volume") {
+		t.Errorf("expected counter_volume to fall back to baseVolume on reversal, got query: %s", query)
+	}
+}
+
+// TestCreateCandleQueryUsesRequestedBucket verifies that aggregateBy is threaded through to
+// getTitleField rather than always grouping by ledger.
+func TestCreateCandleQueryUsesRequestedBucket(t *testing.T) {
+	query := createCandleQuery(testSource, testDest, "", "", "day")
+	if !strings.Contains(query, "TIMESTAMP_TRUNC(L.closed_at, DAY)") {
+		t.Errorf("expected aggregateBy \"day\" to bucket by TIMESTAMP_TRUNC(L.closed_at, DAY), got query: %s", query)
+	}
+}
+
+// TestCreateCandleQueryDisambiguatesAssetColumns verifies that the candles CTE aliases B and C's
+// asset_code/asset_issuer columns to distinct names, since selecting both unaliased would make
+// BigQuery reject the outer query's bare "candles.asset_code" reference as ambiguous.
+func TestCreateCandleQueryDisambiguatesAssetColumns(t *testing.T) {
+	query := createCandleQuery(testSource, testDest, "", "", "ledger")
+
+	if !strings.Contains(query, "B.asset_code AS base_asset_code, B.asset_issuer AS base_asset_issuer, C.asset_code AS counter_asset_code, C.asset_issuer AS counter_asset_issuer") {
+		t.Errorf("expected the candles CTE to alias B/C's asset_code and asset_issuer to distinct names, got query: %s", query)
+	}
+	if strings.Contains(query, "candles.asset_code") || strings.Contains(query, "candles.asset_issuer") {
+		t.Errorf("expected no references to the ambiguous bare candles.asset_code/asset_issuer, got query: %s", query)
+	}
+	if !strings.Contains(query, "candles.base_asset_code=") || !strings.Contains(query, "candles.base_asset_issuer=") {
+		t.Errorf("expected the reversal CASE to key off candles.base_asset_code/base_asset_issuer, got query: %s", query)
+	}
+}
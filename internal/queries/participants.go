@@ -0,0 +1,106 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// TopParticipantResult holds a single Stellar account's traded volume on a corridor, broken out
+// by raw base/counter volume and by buy/sell (ask/bid) side.
+type TopParticipantResult struct {
+	Account          string  `bigquery:"account"`
+	RawBaseVolume    float64 `bigquery:"raw_base_volume"`
+	RawCounterVolume float64 `bigquery:"raw_counter_volume"`
+	AskVolume        float64 `bigquery:"ask_volume"`
+	BidVolume        float64 `bigquery:"bid_volume"`
+}
+
+// RunTopParticipantsQuery queries BigQuery for the top limit accounts by traded volume on the
+// specified source/dest corridor within the timestamp range, and returns the results.
+func RunTopParticipantsQuery(ctx context.Context, source, dest Asset, startUnixTimestamp, endUnixTimestamp string, limit int, client *bigquery.Client) ([]TopParticipantResult, error) {
+	query, params := createTopParticipantsQuery(source, dest, startUnixTimestamp, endUnixTimestamp, limit)
+	it, err := runParameterizedQuery(ctx, query, params, client)
+	if err != nil {
+		return nil, fmt.Errorf("error running query \n%s\n%v", query, err)
+	}
+
+	var results []TopParticipantResult
+	for {
+		var res TopParticipantResult
+		if err := it.Next(&res); err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing results from query: %v", err)
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// createTopParticipantsQuery returns a query that finds the top accounts trading a source/dest
+// corridor, ranked by total volume. Each trade is attributed to both the base and counter account,
+// so accounts appear once regardless of which side of the trade they were on. Because the WHERE
+// clause matches both the normal (base=source, counter=dest) and reversed market orientation,
+// base_amount/counter_amount are normalized into source_amount/dest_amount per row (the same
+// base_is_source CASE WHEN createRateQuery/createCandleQuery use) before summing, so
+// raw_base_volume/raw_counter_volume are always denominated in the source/dest asset respectively
+// regardless of which way a given row happened to be oriented. is_source_seller marks which rows
+// sold the source asset, letting the final SELECT split traded volume into ask (selling source)
+// and bid (buying source) volume per account. Asset codes/issuers and the timestamp range are
+// bound as query parameters rather than interpolated into the SQL string.
+func createTopParticipantsQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp string, limit int) (string, []bigquery.QueryParameter) {
+	baseAssetMatch := "(B.asset_code=@source_code AND B.asset_issuer=@source_issuer AND C.asset_code=@dest_code AND C.asset_issuer=@dest_issuer)"
+	counterAssetMatch := "(C.asset_code=@source_code AND C.asset_issuer=@source_issuer AND B.asset_code=@dest_code AND B.asset_issuer=@dest_issuer)"
+	baseIsSource := "(B.asset_code=@source_code AND B.asset_issuer=@source_issuer)"
+	sourceAmount := fmt.Sprintf("CASE WHEN %s THEN T.base_amount ELSE T.counter_amount END", baseIsSource)
+	destAmount := fmt.Sprintf("CASE WHEN %s THEN T.counter_amount ELSE T.base_amount END", baseIsSource)
+
+	joins := " FROM `crypto-stellar.crypto_stellar.history_trades` T" +
+		" JOIN `crypto-stellar.crypto_stellar.history_assets` B ON B.id=T.base_asset_id" +
+		" JOIN `crypto-stellar.crypto_stellar.history_assets` C ON C.id=T.counter_asset_id" +
+		" JOIN `crypto-stellar.crypto_stellar.history_ledgers` L ON L.closed_at=T.ledger_closed_at"
+	where := fmt.Sprintf(" WHERE (%s OR %s)", baseAssetMatch, counterAssetMatch)
+
+	params := []bigquery.QueryParameter{
+		{Name: "source_code", Value: source.Code},
+		{Name: "source_issuer", Value: source.Issuer},
+		{Name: "dest_code", Value: dest.Code},
+		{Name: "dest_issuer", Value: dest.Issuer},
+	}
+
+	if startUnixTimestamp != "" && endUnixTimestamp != "" {
+		where += " AND L.closed_at BETWEEN TIMESTAMP_SECONDS(CAST(@start_ts AS INT64)) AND TIMESTAMP_SECONDS(CAST(@end_ts AS INT64))"
+		params = append(params,
+			bigquery.QueryParameter{Name: "start_ts", Value: startUnixTimestamp},
+			bigquery.QueryParameter{Name: "end_ts", Value: endUnixTimestamp},
+		)
+	}
+
+	query := "WITH participant_trades AS ("
+	query += fmt.Sprintf(" SELECT BA.account_id AS account, %s AS source_amount, %s AS dest_amount, %s AS is_source_seller", sourceAmount, destAmount, baseIsSource)
+	query += joins
+	query += " JOIN `crypto-stellar.crypto_stellar.history_accounts` BA ON BA.id=T.base_account_id"
+	query += where
+	query += " UNION ALL"
+	query += fmt.Sprintf(" SELECT CA.account_id AS account, %s AS source_amount, %s AS dest_amount, NOT (%s) AS is_source_seller", sourceAmount, destAmount, baseIsSource)
+	query += joins
+	query += " JOIN `crypto-stellar.crypto_stellar.history_accounts` CA ON CA.id=T.counter_account_id"
+	query += where
+	query += ")"
+
+	query += " SELECT account,"
+	query += " SUM(source_amount) AS raw_base_volume,"
+	query += " SUM(dest_amount) AS raw_counter_volume,"
+	query += " SUM(CASE WHEN is_source_seller THEN source_amount ELSE 0 END) AS ask_volume,"
+	query += " SUM(CASE WHEN is_source_seller THEN 0 ELSE dest_amount END) AS bid_volume"
+	query += " FROM participant_trades"
+	query += " GROUP BY account"
+	query += " ORDER BY raw_base_volume + raw_counter_volume DESC"
+	query += fmt.Sprintf(" LIMIT %d", limit)
+	return query, params
+}
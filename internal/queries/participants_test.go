@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCreateTopParticipantsQueryNormalizesBaseCounterVolume verifies that raw_base_volume and
+// raw_counter_volume are summed from amounts normalized to source/dest (via the base_is_source
+// CASE WHEN), not raw base_amount/counter_amount that can flip denomination row to row.
+func TestCreateTopParticipantsQueryNormalizesBaseCounterVolume(t *testing.T) {
+	query, _ := createTopParticipantsQuery(testSource, testDest, "", "", 10)
+
+	if strings.Contains(query, "SUM(base_amount)") || strings.Contains(query, "SUM(counter_amount)") {
+		t.Errorf("expected raw volumes to sum normalized source_amount/dest_amount, not raw base_amount/counter_amount: %s", query)
+	}
+	if !strings.Contains(query, "SUM(source_amount) AS raw_base_volume") || !strings.Contains(query, "SUM(dest_amount) AS raw_counter_volume") {
+		t.Errorf("expected raw_base_volume/raw_counter_volume to sum normalized source_amount/dest_amount: %s", query)
+	}
+	if !strings.Contains(query, "is_source_seller") {
+		t.Errorf("expected ask/bid split to key off a normalized is_source_seller flag: %s", query)
+	}
+}
+
+// TestCreateTopParticipantsQueryBindsAssetsAsParameters verifies asset codes/issuers and the
+// timestamp range are bound as query parameters rather than interpolated into the query string,
+// the same way createRateQuery binds them.
+func TestCreateTopParticipantsQueryBindsAssetsAsParameters(t *testing.T) {
+	query, params := createTopParticipantsQuery(testSource, testDest, "1000", "2000", 10)
+	if strings.Contains(query, testSource.Issuer) || strings.Contains(query, testDest.Issuer) {
+		t.Errorf("expected asset issuers to be bound as parameters, not interpolated into the query: %s", query)
+	}
+
+	want := map[string]interface{}{
+		"source_code": testSource.Code, "source_issuer": testSource.Issuer,
+		"dest_code": testDest.Code, "dest_issuer": testDest.Issuer,
+		"start_ts": "1000", "end_ts": "2000",
+	}
+	if len(params) != len(want) {
+		t.Fatalf("expected %d query parameters, got %d: %v", len(want), len(params), params)
+	}
+	for _, p := range params {
+		if want[p.Name] != p.Value {
+			t.Errorf("parameter %s: expected %v, got %v", p.Name, want[p.Name], p.Value)
+		}
+	}
+}
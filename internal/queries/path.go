@@ -0,0 +1,163 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// createPathRateQuery returns a query that synthesizes a rate across a multi-hop path by building
+// one orderbooks CTE per hop (the same bid/ask averaging createRateQuery uses for a direct
+// corridor), then joining the hops on their shared bucket title and multiplying each hop's rate
+// together to get the end-to-end source->dest rate. fees is applied once per hop, keyed on each
+// hop's dest asset issuer, since a path payment pays that hop's issuer fee on every intermediate
+// leg of the route, not just on arrival at the final dest.
+func createPathRateQuery(path []Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, fees FeeSchedule) (string, []bigquery.QueryParameter) {
+	titleField := getTitleField("E.ledger_id", "L.closed_at", aggregateBy)
+
+	var ctes []string
+	var params []bigquery.QueryParameter
+	var hopNames []string
+
+	for i := 0; i < len(path)-1; i++ {
+		hop := fmt.Sprintf("hop%d", i)
+		hopNames = append(hopNames, hop)
+		source, dest := path[i], path[i+1]
+
+		sourceCodeParam := fmt.Sprintf("%s_source_code", hop)
+		sourceIssuerParam := fmt.Sprintf("%s_source_issuer", hop)
+		destCodeParam := fmt.Sprintf("%s_dest_code", hop)
+		destIssuerParam := fmt.Sprintf("%s_dest_issuer", hop)
+
+		normalMatch := fmt.Sprintf("(M.base_code=@%s AND M.base_issuer=@%s AND M.counter_code=@%s AND M.counter_issuer=@%s)",
+			sourceCodeParam, sourceIssuerParam, destCodeParam, destIssuerParam)
+		reverseMatch := fmt.Sprintf("(M.base_code=@%s AND M.base_issuer=@%s AND M.counter_code=@%s AND M.counter_issuer=@%s)",
+			destCodeParam, destIssuerParam, sourceCodeParam, sourceIssuerParam)
+
+		cte := fmt.Sprintf(" %s AS (", hop)
+		cte += fmt.Sprintf(" SELECT orderbooks.title, (CASE WHEN orderbooks.base_code=@%s AND orderbooks.base_issuer=@%s", sourceCodeParam, sourceIssuerParam)
+		cte += " THEN (orderbooks.askPrices[OFFSET(0)]+orderbooks.bidPrices[OFFSET(0)])/2"
+		cte += " ELSE 1/((orderbooks.askPrices[OFFSET(0)]+orderbooks.bidPrices[OFFSET(0)])/2) END) AS rate FROM ("
+		cte += fmt.Sprintf(" SELECT %s, M.base_code, M.base_issuer, M.counter_code, M.counter_issuer,", titleField)
+		cte += ` ARRAY_AGG(CASE WHEN O.action="b" THEN O.price END IGNORE NULLS ORDER BY O.price DESC) AS bidPrices,`
+		cte += ` ARRAY_AGG(CASE WHEN O.action="s" THEN O.price END IGNORE NULLS ORDER BY O.price ASC) AS askPrices,`
+		cte += " FROM `hubble-261722.liquidity_data.fact_offer_events` AS E"
+		cte += " INNER JOIN `hubble-261722.liquidity_data.dim_offers` O ON (E.offer_instance_id = O.dim_offer_id)"
+		cte += " INNER JOIN `hubble-261722.liquidity_data.dim_markets` M ON (M.market_id = O.market_id)"
+		cte += " INNER JOIN `hubble-261722.crypto_stellar_internal.history_ledgers` L ON (L.sequence = E.ledger_id)"
+		cte += fmt.Sprintf(" WHERE (%s OR %s)", normalMatch, reverseMatch)
+
+		params = append(params,
+			bigquery.QueryParameter{Name: sourceCodeParam, Value: source.Code},
+			bigquery.QueryParameter{Name: sourceIssuerParam, Value: source.Issuer},
+			bigquery.QueryParameter{Name: destCodeParam, Value: dest.Code},
+			bigquery.QueryParameter{Name: destIssuerParam, Value: dest.Issuer},
+		)
+
+		if startUnixTimestamp != "" && endUnixTimestamp != "" {
+			cte += " AND L.closed_at BETWEEN TIMESTAMP_SECONDS(CAST(@start_ts AS INT64)) AND TIMESTAMP_SECONDS(CAST(@end_ts AS INT64))"
+		}
+
+		cte += " GROUP by title, M.base_code, M.base_issuer, M.counter_code, M.counter_issuer) orderbooks"
+		cte += " WHERE (orderbooks.askPrices[OFFSET(0)]+orderbooks.bidPrices[OFFSET(0)])/2 IS NOT NULL)"
+		ctes = append(ctes, cte)
+	}
+
+	if startUnixTimestamp != "" && endUnixTimestamp != "" {
+		params = append(params,
+			bigquery.QueryParameter{Name: "start_ts", Value: startUnixTimestamp},
+			bigquery.QueryParameter{Name: "end_ts", Value: endUnixTimestamp},
+		)
+	}
+
+	query := "WITH" + strings.Join(ctes, ",")
+
+	rateExpr := hopNames[0] + ".rate"
+	for _, hop := range hopNames[1:] {
+		rateExpr += " * " + hop + ".rate"
+	}
+
+	// Each hop lands on a new issuer, and that issuer's path-payment fee (if any) is deducted on
+	// arrival, so the multiplier is the product of feeMultiplier for every hop's dest asset, not
+	// just the final one.
+	hopFeeMultipliers := make([]string, 0, len(path)-1)
+	for _, hopDest := range path[1:] {
+		hopFeeMultipliers = append(hopFeeMultipliers, feeMultiplier(fees, hopDest.Issuer))
+	}
+	feeExpr := strings.Join(hopFeeMultipliers, " * ")
+
+	query += fmt.Sprintf(" SELECT %s.title, (%s) * %s AS rate FROM %s", hopNames[0], rateExpr, feeExpr, hopNames[0])
+	for _, hop := range hopNames[1:] {
+		query += fmt.Sprintf(" JOIN %s ON %s.title = %s.title", hop, hopNames[0], hop)
+	}
+	query += fmt.Sprintf(" ORDER BY %s.title ASC LIMIT %d", hopNames[0], queryLimit)
+
+	return query, params
+}
+
+// candidatePaths returns the direct source->dest corridor plus one 3-asset path through each
+// intermediate in universe, skipping any intermediate that is itself the source or dest asset.
+func candidatePaths(source, dest Asset, universe []Asset) [][]Asset {
+	paths := [][]Asset{{source, dest}}
+	for _, intermediate := range universe {
+		if assetEquals(intermediate, source) || assetEquals(intermediate, dest) {
+			continue
+		}
+		paths = append(paths, []Asset{source, intermediate, dest})
+	}
+	return paths
+}
+
+// RunBestPathQuery enumerates candidate intermediates from universe (plus the direct source->dest
+// corridor) and runs RunRateQuery for each resulting path, returning the path with the best
+// (highest) average rate over the window along with that path's rate series. This mirrors how
+// Stellar's path_payment_strict_send picks the best available route when no direct market exists.
+func RunBestPathQuery(ctx context.Context, source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, universe []Asset, fees FeeSchedule, client *bigquery.Client) ([]Asset, []RateResult, error) {
+	var bestPath []Asset
+	var bestResults []RateResult
+	bestAverageRate := 0.0
+	var firstErr error
+
+	for _, path := range candidatePaths(source, dest, universe) {
+		results, err := RunRateQuery(ctx, path, startUnixTimestamp, endUnixTimestamp, aggregateBy, fees, client)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if len(results) == 0 {
+			continue
+		}
+
+		average := averageRate(results)
+		if bestPath == nil || average > bestAverageRate {
+			bestPath = path
+			bestResults = results
+			bestAverageRate = average
+		}
+	}
+
+	if bestPath == nil {
+		if firstErr != nil {
+			return nil, nil, firstErr
+		}
+		return nil, nil, fmt.Errorf("no viable path found between %s:%s and %s:%s", source.Code, source.Issuer, dest.Code, dest.Issuer)
+	}
+
+	return bestPath, bestResults, nil
+}
+
+func averageRate(results []RateResult) float64 {
+	var sum float64
+	for _, res := range results {
+		sum += res.Rate
+	}
+	return sum / float64(len(results))
+}
+
+func assetEquals(a, b Asset) bool {
+	return a.Code == b.Code && a.Issuer == b.Issuer
+}
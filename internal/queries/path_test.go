@@ -0,0 +1,64 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+)
+
+var testIntermediate = Asset{Code: "USD", Issuer: "GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX"}
+
+func TestCreatePathRateQueryJoinsOneHopPerAsset(t *testing.T) {
+	path := []Asset{testSource, testIntermediate, testDest}
+	query, params := createPathRateQuery(path, "1000", "2000", "ledger", nil)
+
+	for _, hop := range []string{"hop0", "hop1"} {
+		if !strings.Contains(query, hop) {
+			t.Errorf("expected query to contain a CTE for %s, got: %s", hop, query)
+		}
+	}
+	if !strings.Contains(query, "hop0.rate * hop1.rate") {
+		t.Errorf("expected query to multiply the per-hop rates together, got: %s", query)
+	}
+
+	wantParamNames := []string{
+		"hop0_source_code", "hop0_source_issuer", "hop0_dest_code", "hop0_dest_issuer",
+		"hop1_source_code", "hop1_source_issuer", "hop1_dest_code", "hop1_dest_issuer",
+		"start_ts", "end_ts",
+	}
+	if len(params) != len(wantParamNames) {
+		t.Fatalf("expected %d query parameters, got %d: %v", len(wantParamNames), len(params), params)
+	}
+}
+
+// TestCreatePathRateQueryAppliesFeePerHop verifies that each hop's dest issuer fee is deducted
+// independently, rather than only the final dest asset's, since a path payment pays every
+// intermediate issuer's cut on arrival at that hop.
+func TestCreatePathRateQueryAppliesFeePerHop(t *testing.T) {
+	fees := FeeSchedule{testIntermediate.Issuer: 10, testDest.Issuer: 25}
+	path := []Asset{testSource, testIntermediate, testDest}
+	query, _ := createPathRateQuery(path, "1000", "2000", "ledger", fees)
+
+	if !strings.Contains(query, "(1 - 10/10000.0) * (1 - 25/10000.0)") {
+		t.Errorf("expected the rate to be scaled by both hops' fee multipliers, got query: %s", query)
+	}
+}
+
+func TestCandidatePathsSkipsIntermediatesThatAreSourceOrDest(t *testing.T) {
+	universe := []Asset{testSource, testDest, testIntermediate}
+	paths := candidatePaths(testSource, testDest, universe)
+
+	if len(paths) != 2 {
+		t.Fatalf("expected the direct corridor plus one candidate path through %s, got %d: %v",
+			testIntermediate.Code, len(paths), paths)
+	}
+	if len(paths[1]) != 3 || !assetEquals(paths[1][1], testIntermediate) {
+		t.Errorf("expected the candidate path to route through %s, got %v", testIntermediate.Code, paths[1])
+	}
+}
+
+func TestAverageRate(t *testing.T) {
+	results := []RateResult{{Rate: 1}, {Rate: 2}, {Rate: 3}}
+	if got := averageRate(results); got != 2 {
+		t.Errorf("expected average rate 2, got %v", got)
+	}
+}
@@ -0,0 +1,246 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PostgresBackend implements QueryBackend against a Postgres/TimescaleDB schema mirroring
+// Horizon's history_trades and offer event tables, using time_bucket() for aggregation. This lets
+// operators run the viewer against a local Horizon replica instead of BigQuery.
+type PostgresBackend struct {
+	DB *sql.DB
+}
+
+// NewPostgresBackend returns a QueryBackend backed by the given Postgres/TimescaleDB connection.
+func NewPostgresBackend(db *sql.DB) *PostgresBackend {
+	return &PostgresBackend{DB: db}
+}
+
+// RateSeries implements QueryBackend by averaging the best bid and best ask price from the
+// offer_events hypertable within each time_bucket. It only supports a direct source/dest
+// corridor; multi-hop paths return an error rather than silently dropping the intermediate hops.
+func (b *PostgresBackend) RateSeries(ctx context.Context, path []Asset, start, end, bucket string, fees FeeSchedule) ([]RateResult, error) {
+	if len(path) != 2 {
+		return nil, fmt.Errorf("PostgresBackend.RateSeries only supports a direct source/dest corridor, got a %d-asset path", len(path))
+	}
+
+	query, args, err := createPostgresRateQuery(path[0], path[1], start, end, bucket, fees)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := b.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error running query \n%s\n%v", query, err)
+	}
+	defer rows.Close()
+
+	var results []RateResult
+	for rows.Next() {
+		var res RateResult
+		if err := rows.Scan(&res.Title, &res.Rate); err != nil {
+			return nil, fmt.Errorf("error parsing results from query: %v", err)
+		}
+		results = append(results, res)
+	}
+
+	return results, rows.Err()
+}
+
+// VolumeSeries implements QueryBackend by computing OHLC and base/counter volume from the
+// history_trades hypertable within each time_bucket.
+func (b *PostgresBackend) VolumeSeries(ctx context.Context, source, dest Asset, start, end, bucket string) ([]CandleResult, error) {
+	query, args, err := createPostgresCandleQuery(source, dest, start, end, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := b.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error running query \n%s\n%v", query, err)
+	}
+	defer rows.Close()
+
+	var results []CandleResult
+	for rows.Next() {
+		var res CandleResult
+		if err := rows.Scan(&res.Title, &res.Open, &res.High, &res.Low, &res.Close, &res.BaseVolume, &res.CounterVolume); err != nil {
+			return nil, fmt.Errorf("error parsing results from query: %v", err)
+		}
+		results = append(results, res)
+	}
+
+	return results, rows.Err()
+}
+
+// TopParticipants implements QueryBackend by attributing each history_trades row to both its base
+// and counter account and summing normalized source/dest volume per account, mirroring the
+// BigQuery backend's RunTopParticipantsQuery.
+func (b *PostgresBackend) TopParticipants(ctx context.Context, source, dest Asset, start, end string, limit int) ([]TopParticipantResult, error) {
+	query, args := createPostgresTopParticipantsQuery(source, dest, start, end, limit)
+	rows, err := b.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error running query \n%s\n%v", query, err)
+	}
+	defer rows.Close()
+
+	var results []TopParticipantResult
+	for rows.Next() {
+		var res TopParticipantResult
+		if err := rows.Scan(&res.Account, &res.RawBaseVolume, &res.RawCounterVolume, &res.AskVolume, &res.BidVolume); err != nil {
+			return nil, fmt.Errorf("error parsing results from query: %v", err)
+		}
+		results = append(results, res)
+	}
+
+	return results, rows.Err()
+}
+
+// postgresBucketInterval translates the app's aggregateBy vocabulary ("minute", "hour", "day",
+// "week") into the interval literal time_bucket() expects. "ledger" has no Postgres equivalent
+// (the Postgres schema has no per-ledger grouping to bucket by), so it and any other unrecognized
+// value return an error instead of being bound as a bogus interval that Postgres would reject.
+func postgresBucketInterval(aggregateBy string) (string, error) {
+	switch aggregateBy {
+	case "minute":
+		return "1 minute", nil
+	case "hour":
+		return "1 hour", nil
+	case "day":
+		return "1 day", nil
+	case "week":
+		return "1 week", nil
+	default:
+		return "", fmt.Errorf("PostgresBackend does not support aggregateBy %q; use minute, hour, day, or week", aggregateBy)
+	}
+}
+
+// createPostgresRateQuery returns a parameterized query and its arguments that compute the
+// on-DEX mid-price rate between source and dest from the offer_events hypertable, bucketed by
+// time_bucket(bucket, closed_at). fees is an optional FeeSchedule (nil or empty reproduces the
+// raw mid-price rate), applied with the same feeMultiplier the BigQuery backend uses.
+func createPostgresRateQuery(source, dest Asset, start, end, bucket string, fees FeeSchedule) (string, []interface{}, error) {
+	interval, err := postgresBucketInterval(bucket)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := `WITH orderbooks AS (
+		SELECT time_bucket($1, e.closed_at) AS title, m.base_code, m.base_issuer, m.counter_code, m.counter_issuer,
+		MAX(o.price) FILTER (WHERE o.action = 'b') AS best_bid,
+		MIN(o.price) FILTER (WHERE o.action = 's') AS best_ask
+		FROM offer_events e
+		JOIN offers o ON e.offer_id = o.id
+		JOIN markets m ON o.market_id = m.id
+		WHERE ((m.base_code = $2 AND m.base_issuer = $3 AND m.counter_code = $4 AND m.counter_issuer = $5)
+			OR (m.base_code = $4 AND m.base_issuer = $5 AND m.counter_code = $2 AND m.counter_issuer = $3))`
+	args := []interface{}{interval, source.Code, source.Issuer, dest.Code, dest.Issuer}
+
+	if start != "" && end != "" {
+		query += " AND e.closed_at BETWEEN to_timestamp($6) AND to_timestamp($7)"
+		args = append(args, start, end)
+	}
+
+	query += ` GROUP BY title, m.base_code, m.base_issuer, m.counter_code, m.counter_issuer
+	)
+	SELECT title, (CASE WHEN base_code = $2 AND base_issuer = $3
+		THEN (best_bid + best_ask) / 2
+		ELSE 1 / ((best_bid + best_ask) / 2) END) * ` + feeMultiplier(fees, dest.Issuer) + ` AS rate
+	FROM orderbooks
+	WHERE best_bid IS NOT NULL AND best_ask IS NOT NULL
+	ORDER BY title ASC
+	LIMIT ` + fmt.Sprint(queryLimit)
+
+	return query, args, nil
+}
+
+// createPostgresCandleQuery returns a parameterized query and its arguments that compute OHLC
+// prices and base/counter volume between source and dest from the history_trades hypertable,
+// bucketed by time_bucket(bucket, ledger_closed_at).
+func createPostgresCandleQuery(source, dest Asset, start, end, bucket string) (string, []interface{}, error) {
+	interval, err := postgresBucketInterval(bucket)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query := `WITH candles AS (
+		SELECT time_bucket($1, t.ledger_closed_at) AS title, b.asset_code, b.asset_issuer,
+		(ARRAY_AGG(t.counter_amount / t.base_amount ORDER BY t.ledger_closed_at ASC))[1] AS open_price,
+		(ARRAY_AGG(t.counter_amount / t.base_amount ORDER BY t.ledger_closed_at DESC))[1] AS close_price,
+		MIN(t.counter_amount / t.base_amount) AS low_price,
+		MAX(t.counter_amount / t.base_amount) AS high_price,
+		SUM(t.base_amount) AS base_volume,
+		SUM(t.counter_amount) AS counter_volume
+		FROM history_trades t
+		JOIN history_assets b ON b.id = t.base_asset_id
+		JOIN history_assets c ON c.id = t.counter_asset_id
+		WHERE ((b.asset_code = $2 AND b.asset_issuer = $3 AND c.asset_code = $4 AND c.asset_issuer = $5)
+			OR (c.asset_code = $2 AND c.asset_issuer = $3 AND b.asset_code = $4 AND b.asset_issuer = $5))`
+	args := []interface{}{interval, source.Code, source.Issuer, dest.Code, dest.Issuer}
+
+	if start != "" && end != "" {
+		query += " AND t.ledger_closed_at BETWEEN to_timestamp($6) AND to_timestamp($7)"
+		args = append(args, start, end)
+	}
+
+	query += ` GROUP BY title, b.asset_code, b.asset_issuer
+	)
+	SELECT title,
+		CASE WHEN asset_code = $2 AND asset_issuer = $3 THEN open_price ELSE 1 / open_price END,
+		CASE WHEN asset_code = $2 AND asset_issuer = $3 THEN high_price ELSE 1 / low_price END,
+		CASE WHEN asset_code = $2 AND asset_issuer = $3 THEN low_price ELSE 1 / high_price END,
+		CASE WHEN asset_code = $2 AND asset_issuer = $3 THEN close_price ELSE 1 / close_price END,
+		CASE WHEN asset_code = $2 AND asset_issuer = $3 THEN base_volume ELSE counter_volume END,
+		CASE WHEN asset_code = $2 AND asset_issuer = $3 THEN counter_volume ELSE base_volume END
+	FROM candles
+	ORDER BY title ASC
+	LIMIT ` + fmt.Sprint(queryLimit)
+
+	return query, args, nil
+}
+
+// createPostgresTopParticipantsQuery returns a parameterized query and its arguments that find the
+// top accounts trading a source/dest corridor from the history_trades hypertable, mirroring
+// createTopParticipantsQuery's base/counter-account UNION ALL and normalized source/dest volume.
+func createPostgresTopParticipantsQuery(source, dest Asset, start, end string, limit int) (string, []interface{}) {
+	baseIsSource := "(b.asset_code = $1 AND b.asset_issuer = $2)"
+	sourceAmount := fmt.Sprintf("CASE WHEN %s THEN t.base_amount ELSE t.counter_amount END", baseIsSource)
+	destAmount := fmt.Sprintf("CASE WHEN %s THEN t.counter_amount ELSE t.base_amount END", baseIsSource)
+
+	joins := ` FROM history_trades t
+		JOIN history_assets b ON b.id = t.base_asset_id
+		JOIN history_assets c ON c.id = t.counter_asset_id`
+	where := ` WHERE ((b.asset_code = $1 AND b.asset_issuer = $2 AND c.asset_code = $3 AND c.asset_issuer = $4)
+		OR (c.asset_code = $1 AND c.asset_issuer = $2 AND b.asset_code = $3 AND b.asset_issuer = $4))`
+	args := []interface{}{source.Code, source.Issuer, dest.Code, dest.Issuer}
+
+	if start != "" && end != "" {
+		where += " AND t.ledger_closed_at BETWEEN to_timestamp($5) AND to_timestamp($6)"
+		args = append(args, start, end)
+	}
+
+	query := `WITH participant_trades AS (
+		SELECT ba.account AS account, ` + sourceAmount + ` AS source_amount, ` + destAmount + ` AS dest_amount, ` + baseIsSource + ` AS is_source_seller` +
+		joins + `
+		JOIN history_accounts ba ON ba.id = t.base_account_id` +
+		where + `
+		UNION ALL
+		SELECT ca.account AS account, ` + sourceAmount + ` AS source_amount, ` + destAmount + ` AS dest_amount, NOT (` + baseIsSource + `) AS is_source_seller` +
+		joins + `
+		JOIN history_accounts ca ON ca.id = t.counter_account_id` +
+		where + `
+	)
+	SELECT account,
+		SUM(source_amount) AS raw_base_volume,
+		SUM(dest_amount) AS raw_counter_volume,
+		SUM(CASE WHEN is_source_seller THEN source_amount ELSE 0 END) AS ask_volume,
+		SUM(CASE WHEN is_source_seller THEN 0 ELSE dest_amount END) AS bid_volume
+	FROM participant_trades
+	GROUP BY account
+	ORDER BY SUM(source_amount) + SUM(dest_amount) DESC
+	LIMIT ` + fmt.Sprint(limit)
+
+	return query, args
+}
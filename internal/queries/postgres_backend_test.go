@@ -0,0 +1,146 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCreatePostgresRateQueryBindsAssetsAsParameters verifies asset codes/issuers are bound as
+// positional query arguments rather than interpolated into the SQL string.
+func TestCreatePostgresRateQueryBindsAssetsAsParameters(t *testing.T) {
+	query, args, err := createPostgresRateQuery(testSource, testDest, "1000", "2000", "hour", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(query, testSource.Issuer) || strings.Contains(query, testDest.Issuer) {
+		t.Errorf("expected asset issuers to be bound as arguments, not interpolated into the query: %s", query)
+	}
+
+	want := []interface{}{"1 hour", testSource.Code, testSource.Issuer, testDest.Code, testDest.Issuer, "1000", "2000"}
+	if len(args) != len(want) {
+		t.Fatalf("expected %d arguments, got %d: %v", len(want), len(args), args)
+	}
+	for i, v := range want {
+		if args[i] != v {
+			t.Errorf("argument %d: expected %v, got %v", i, v, args[i])
+		}
+	}
+}
+
+// TestCreatePostgresRateQueryTranslatesAggregateByToInterval verifies the app's aggregateBy
+// vocabulary is translated into a Postgres interval literal rather than bound as-is, since
+// time_bucket() rejects bucket names like "minute" or "day" outright.
+func TestCreatePostgresRateQueryTranslatesAggregateByToInterval(t *testing.T) {
+	cases := map[string]string{"minute": "1 minute", "hour": "1 hour", "day": "1 day", "week": "1 week"}
+	for aggregateBy, interval := range cases {
+		_, args, err := createPostgresRateQuery(testSource, testDest, "", "", aggregateBy, nil)
+		if err != nil {
+			t.Fatalf("unexpected error for aggregateBy %q: %v", aggregateBy, err)
+		}
+		if args[0] != interval {
+			t.Errorf("aggregateBy %q: expected bucket interval %q, got %v", aggregateBy, interval, args[0])
+		}
+	}
+}
+
+// TestCreatePostgresRateQueryRejectsLedgerBucket verifies that "ledger" (which has no Postgres
+// equivalent, since the schema has no per-ledger grouping) is rejected explicitly rather than
+// bound as a bogus time_bucket() interval.
+func TestCreatePostgresRateQueryRejectsLedgerBucket(t *testing.T) {
+	if _, _, err := createPostgresRateQuery(testSource, testDest, "", "", "ledger", nil); err == nil {
+		t.Error("expected an error for aggregateBy \"ledger\", got nil")
+	}
+}
+
+// TestCreatePostgresRateQueryZeroFeeScheduleReproducesRawRate verifies that a nil or zero-value
+// FeeSchedule leaves the returned rate expression unchanged, mirroring the BigQuery backend.
+func TestCreatePostgresRateQueryZeroFeeScheduleReproducesRawRate(t *testing.T) {
+	for _, fees := range []FeeSchedule{nil, {}, {testDest.Issuer: 0}} {
+		query, _, err := createPostgresRateQuery(testSource, testDest, "", "", "hour", fees)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(query, "END) * 1 AS rate") {
+			t.Errorf("expected zero fee schedule %v to leave the rate unscaled, got query: %s", fees, query)
+		}
+	}
+}
+
+// TestCreatePostgresRateQueryAppliesFeeSchedule verifies that a non-zero FeeSchedule entry for the
+// dest issuer scales the returned rate down by the configured bps, using the same feeMultiplier
+// helper the BigQuery backend uses.
+func TestCreatePostgresRateQueryAppliesFeeSchedule(t *testing.T) {
+	fees := FeeSchedule{testDest.Issuer: 25}
+	query, _, err := createPostgresRateQuery(testSource, testDest, "", "", "hour", fees)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(query, "END) * (1 - 25/10000.0) AS rate") {
+		t.Errorf("expected fee schedule to scale the rate by (1 - 25/10000.0), got query: %s", query)
+	}
+}
+
+// TestPostgresBackendRateSeriesRejectsMultiHopPaths verifies that a path longer than source/dest
+// returns an explicit error instead of silently dropping the intermediate hops.
+func TestPostgresBackendRateSeriesRejectsMultiHopPaths(t *testing.T) {
+	b := &PostgresBackend{}
+	path := []Asset{testSource, testDest, testSource}
+	if _, err := b.RateSeries(nil, path, "", "", "hour", nil); err == nil {
+		t.Error("expected an error for a multi-hop path, got nil")
+	}
+}
+
+// TestCreatePostgresCandleQueryTranslatesAggregateByToInterval verifies the app's aggregateBy
+// vocabulary is translated into a Postgres interval literal before being bound to time_bucket(),
+// the same translation createPostgresRateQuery applies.
+func TestCreatePostgresCandleQueryTranslatesAggregateByToInterval(t *testing.T) {
+	_, args, err := createPostgresCandleQuery(testSource, testDest, "", "", "day")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args[0] != "1 day" {
+		t.Errorf("expected bucket interval \"1 day\", got %v", args[0])
+	}
+}
+
+// TestCreatePostgresCandleQueryRejectsLedgerBucket verifies that "ledger" is rejected explicitly
+// rather than bound as a bogus time_bucket() interval.
+func TestCreatePostgresCandleQueryRejectsLedgerBucket(t *testing.T) {
+	if _, _, err := createPostgresCandleQuery(testSource, testDest, "", "", "ledger"); err == nil {
+		t.Error("expected an error for aggregateBy \"ledger\", got nil")
+	}
+}
+
+// TestCreatePostgresTopParticipantsQueryBindsAssetsAsParameters verifies asset codes/issuers are
+// bound as positional query arguments rather than interpolated into the SQL string.
+func TestCreatePostgresTopParticipantsQueryBindsAssetsAsParameters(t *testing.T) {
+	query, args := createPostgresTopParticipantsQuery(testSource, testDest, "1000", "2000", 20)
+	if strings.Contains(query, testSource.Issuer) || strings.Contains(query, testDest.Issuer) {
+		t.Errorf("expected asset issuers to be bound as arguments, not interpolated into the query: %s", query)
+	}
+
+	want := []interface{}{testSource.Code, testSource.Issuer, testDest.Code, testDest.Issuer, "1000", "2000"}
+	if len(args) != len(want) {
+		t.Fatalf("expected %d arguments, got %d: %v", len(want), len(args), args)
+	}
+	for i, v := range want {
+		if args[i] != v {
+			t.Errorf("argument %d: expected %v, got %v", i, v, args[i])
+		}
+	}
+}
+
+// TestCreatePostgresTopParticipantsQueryAttributesBothSides verifies each trade is attributed to
+// both its base and counter account via a UNION ALL, mirroring createTopParticipantsQuery.
+func TestCreatePostgresTopParticipantsQueryAttributesBothSides(t *testing.T) {
+	query, _ := createPostgresTopParticipantsQuery(testSource, testDest, "", "", 20)
+	if !strings.Contains(query, "JOIN history_accounts ba ON ba.id = t.base_account_id") {
+		t.Errorf("expected a join attributing trades to the base account, got query: %s", query)
+	}
+	if !strings.Contains(query, "JOIN history_accounts ca ON ca.id = t.counter_account_id") {
+		t.Errorf("expected a join attributing trades to the counter account, got query: %s", query)
+	}
+	if !strings.Contains(query, "UNION ALL") {
+		t.Errorf("expected the base and counter account attributions to be combined via UNION ALL, got query: %s", query)
+	}
+}
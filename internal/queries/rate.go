@@ -1,16 +1,60 @@
 package queries
 
 import (
+	"context"
 	"fmt"
 
 	"cloud.google.com/go/bigquery"
 	"google.golang.org/api/iterator"
 )
 
-// RunRateQuery queries BigQuery for the volume of assets over the specified corridor and returns the results
-func RunRateQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, client *bigquery.Client) ([]RateResult, error) {
-	query := createRateQuery(source, dest, startUnixTimestamp, endUnixTimestamp, aggregateBy)
-	it, err := runQuery(query, client)
+// runParameterizedQuery runs query on client with params bound as BigQuery query parameters,
+// rather than interpolated into the query string. Binding parameters lets BigQuery's query cache
+// hit across requests that only differ by corridor or time range, and removes any injection
+// surface if asset codes/issuers ever originate from user input. ctx is threaded through to the
+// BigQuery job so a caller's cancellation or timeout actually cancels the job instead of leaving
+// it to bill to completion.
+func runParameterizedQuery(ctx context.Context, query string, params []bigquery.QueryParameter, client *bigquery.Client) (*bigquery.RowIterator, error) {
+	q := client.Query(query)
+	q.Parameters = params
+	return q.Read(ctx)
+}
+
+// FeeSchedule maps an asset issuer to the fee, in basis points, that issuer charges on a
+// path payment. It is used to compute an effective rate net of those issuer path-payment fees,
+// rather than the raw mid-price. No separate DEX offer fee is deducted.
+type FeeSchedule map[string]int
+
+// feeMultiplier returns the SQL fraction of a rate that survives the fee schedule's cut for the
+// given issuer, e.g. "(1 - 25/10000.0)" for a 25 bps fee. An issuer with no entry (or a zero-value
+// FeeSchedule) pays no fee, so the multiplier is 1 and the rate is unchanged.
+func feeMultiplier(fees FeeSchedule, issuer string) string {
+	bps, ok := fees[issuer]
+	if !ok || bps == 0 {
+		return "1"
+	}
+	return fmt.Sprintf("(1 - %d/10000.0)", bps)
+}
+
+// RunRateQuery queries BigQuery for the rate between assets over the specified corridor and returns the results.
+// path is an ordered slice of at least two assets (source, any intermediates, dest); a two-asset path is the
+// direct corridor, while a longer path synthesizes a rate across each hop, as a path_payment_strict_send would.
+// fees is an optional FeeSchedule (nil or empty reproduces the raw mid-price rate) used to compute an
+// effective rate net of per-issuer path-payment fees; no separate DEX offer fee is deducted.
+func RunRateQuery(ctx context.Context, path []Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, fees FeeSchedule, client *bigquery.Client) ([]RateResult, error) {
+	if len(path) < 2 {
+		return nil, fmt.Errorf("path must contain at least a source and dest asset, got %d", len(path))
+	}
+
+	var query string
+	var params []bigquery.QueryParameter
+	if len(path) == 2 {
+		query, params = createRateQuery(path[0], path[1], startUnixTimestamp, endUnixTimestamp, aggregateBy, fees)
+	} else {
+		query, params = createPathRateQuery(path, startUnixTimestamp, endUnixTimestamp, aggregateBy, fees)
+	}
+
+	it, err := runParameterizedQuery(ctx, query, params, client)
 	if err != nil {
 		return nil, fmt.Errorf("error running query \n%s\n%v", query, err)
 	}
@@ -33,7 +77,7 @@ func RunRateQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggr
 // createRateTradeQuery returns a query that gets the the rate between two assets, grouped by ledger.
 // The volume is calculated by looking at trades involving the assets within the timestamp range.
 // The timestamps are in UTC to ensure they are consistent with the ledger closed_at timestamps.
-func createRateTradeQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string) string {
+func createRateTradeQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, fees FeeSchedule) (string, []bigquery.QueryParameter) {
 	// A sample query is below:
 	// SELECT FORMAT("Ledger %d", L.sequence) AS title,
 	// CASE WHEN ((B.asset_code="NGNT" AND B.asset_issuer="GAWODAROMJ33V5YDFY3NPYTHVYQG7MJXVJ2ND3AOGIHYRWINES6ACCPD") OR
@@ -54,16 +98,14 @@ func createRateTradeQuery(source, dest Asset, startUnixTimestamp, endUnixTimesta
 	// If the assets map as we expect (source -> base and dest -> counter), then the rate
 	// is the counter amount over the base amount. The rate convert from X source assets to Y dest assets
 	// so the units for the rate should be (dest/source = counter/base)
-	baseAssetMatch := fmt.Sprintf("((B.asset_code=\"%s\" AND B.asset_issuer=\"%s\") OR (C.asset_code=\"%s\" AND C.asset_issuer=\"%s\"))",
-		source.Code, source.Issuer, dest.Code, dest.Issuer)
-	baseAssetSelect := "SUM(T.counter_amount)/SUM(T.base_amount)"
+	baseAssetMatch := "((B.asset_code=@source_code AND B.asset_issuer=@source_issuer) OR (C.asset_code=@dest_code AND C.asset_issuer=@dest_issuer))"
+	baseAssetSelect := fmt.Sprintf("SUM(T.counter_amount)/SUM(T.base_amount) * %s", feeMultiplier(fees, dest.Issuer))
 
 	// If the assets map as the opposite of what we expect (source -> counter and dest -> base), then the rate
 	// is the base amount over the counter amount. The rate convert from X source assets to Y dest assets
 	// so the units for the rate should be (dest/source = base/counter)
-	counterAssetMatch := fmt.Sprintf("((C.asset_code=\"%s\" AND C.asset_issuer=\"%s\") OR (B.asset_code=\"%s\" AND B.asset_issuer=\"%s\"))",
-		source.Code, source.Issuer, dest.Code, dest.Issuer)
-	counterAssetSelect := "SUM(T.base_amount)/SUM(T.counter_amount)"
+	counterAssetMatch := "((C.asset_code=@source_code AND C.asset_issuer=@source_issuer) OR (B.asset_code=@dest_code AND B.asset_issuer=@dest_issuer))"
+	counterAssetSelect := fmt.Sprintf("SUM(T.base_amount)/SUM(T.counter_amount) * %s", feeMultiplier(fees, dest.Issuer))
 	titleField := getTitleField("L.sequence", "L.closed_at", aggregateBy)
 
 	query := fmt.Sprintf("SELECT %s, CASE WHEN %s THEN %s WHEN %s THEN %s END AS rate,",
@@ -74,19 +116,30 @@ func createRateTradeQuery(source, dest Asset, startUnixTimestamp, endUnixTimesta
 	query += " JOIN `crypto-stellar.crypto_stellar.history_ledgers` L ON L.closed_at=T.ledger_closed_at"
 	query += fmt.Sprintf(" WHERE (%s OR %s)", baseAssetMatch, counterAssetMatch)
 
+	params := []bigquery.QueryParameter{
+		{Name: "source_code", Value: source.Code},
+		{Name: "source_issuer", Value: source.Issuer},
+		{Name: "dest_code", Value: dest.Code},
+		{Name: "dest_issuer", Value: dest.Issuer},
+	}
+
 	if startUnixTimestamp != "" && endUnixTimestamp != "" {
-		query += fmt.Sprintf(" AND L.closed_at BETWEEN TIMESTAMP_SECONDS(%s) AND TIMESTAMP_SECONDS(%s)", startUnixTimestamp, endUnixTimestamp)
+		query += " AND L.closed_at BETWEEN TIMESTAMP_SECONDS(CAST(@start_ts AS INT64)) AND TIMESTAMP_SECONDS(CAST(@end_ts AS INT64))"
+		params = append(params,
+			bigquery.QueryParameter{Name: "start_ts", Value: startUnixTimestamp},
+			bigquery.QueryParameter{Name: "end_ts", Value: endUnixTimestamp},
+		)
 	}
 
 	query += fmt.Sprintf(" GROUP BY title, B.asset_code, B.asset_issuer, C.asset_code, C.asset_issuer ORDER BY title ASC LIMIT %d", queryLimit)
-	return query
+	return query, params
 }
 
 // createRateQuery returns a query that gets the on-DEX rate between two assets, grouped by ledger.
 // The rate is calculated by looking at historical orderbooks. The average price of the highest bid
 // and the lowest ask are averaged to get the rate at each ledger. The query calculates rates within the timestamp range.
 // The timestamps are in UTC to ensure they are consistent with the ledger closed_at timestamps.
-func createRateQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string) string {
+func createRateQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, aggregateBy string, fees FeeSchedule) (string, []bigquery.QueryParameter) {
 	// A sample query is below:
 	// WITH orderbooks AS (
 	// 		SELECT FORMAT("Ledger %d", E.ledger_id) AS title, M.base_code, M.base_issuer, M.counter_code, M.counter_issuer,
@@ -106,10 +159,8 @@ func createRateQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, a
 	// FROM orderbooks WHERE (orderbooks.askPrices[OFFSET(0)]+orderbooks.bidPrices[OFFSET(0)])/2 IS NOT NULL
 	// ORDER BY orderbooks.title ASC LIMIT 100
 
-	normalMatch := fmt.Sprintf("(M.base_code=\"%s\" AND M.base_issuer=\"%s\" AND M.counter_code=\"%s\" AND M.counter_issuer=\"%s\")",
-		source.Code, source.Issuer, dest.Code, dest.Issuer)
-	reverseMatch := fmt.Sprintf("(M.base_code=\"%s\" AND M.base_issuer=\"%s\" AND M.counter_code=\"%s\" AND M.counter_issuer=\"%s\")",
-		dest.Code, dest.Issuer, source.Code, source.Issuer)
+	normalMatch := "(M.base_code=@source_code AND M.base_issuer=@source_issuer AND M.counter_code=@dest_code AND M.counter_issuer=@dest_issuer)"
+	reverseMatch := "(M.base_code=@dest_code AND M.base_issuer=@dest_issuer AND M.counter_code=@source_code AND M.counter_issuer=@source_issuer)"
 	titleField := getTitleField("E.ledger_id", "L.closed_at", aggregateBy)
 
 	query := "WITH orderbooks AS ("
@@ -122,18 +173,30 @@ func createRateQuery(source, dest Asset, startUnixTimestamp, endUnixTimestamp, a
 	query += " INNER JOIN `hubble-261722.crypto_stellar_internal.history_ledgers` L ON (L.sequence = E.ledger_id)"
 	query += fmt.Sprintf(" WHERE (%s OR %s)", normalMatch, reverseMatch)
 
+	params := []bigquery.QueryParameter{
+		{Name: "source_code", Value: source.Code},
+		{Name: "source_issuer", Value: source.Issuer},
+		{Name: "dest_code", Value: dest.Code},
+		{Name: "dest_issuer", Value: dest.Issuer},
+	}
+
 	if startUnixTimestamp != "" && endUnixTimestamp != "" {
-		query += fmt.Sprintf(" AND L.closed_at BETWEEN TIMESTAMP_SECONDS(%s) AND TIMESTAMP_SECONDS(%s)", startUnixTimestamp, endUnixTimestamp)
+		query += " AND L.closed_at BETWEEN TIMESTAMP_SECONDS(CAST(@start_ts AS INT64)) AND TIMESTAMP_SECONDS(CAST(@end_ts AS INT64))"
+		params = append(params,
+			bigquery.QueryParameter{Name: "start_ts", Value: startUnixTimestamp},
+			bigquery.QueryParameter{Name: "end_ts", Value: endUnixTimestamp},
+		)
 	}
 
 	query += " GROUP by title, M.base_code, M.base_issuer, M.counter_code, M.counter_issuer)"
 
 	rateCalculation := "(orderbooks.askPrices[OFFSET(0)]+orderbooks.bidPrices[OFFSET(0)])/2"
-	baseIsSource := fmt.Sprintf("orderbooks.base_code=\"%s\" AND orderbooks.base_issuer=\"%s\"", source.Code, source.Issuer)
+	baseIsSource := "orderbooks.base_code=@source_code AND orderbooks.base_issuer=@source_issuer"
 
 	// if the base is not the source asset, then our rate is the reversed direction and so we must take the reciprocal
-	query += fmt.Sprintf(" SELECT orderbooks.title, CASE WHEN %s THEN %s ELSE 1/(%s) END AS rate FROM orderbooks", baseIsSource, rateCalculation, rateCalculation)
+	query += fmt.Sprintf(" SELECT orderbooks.title, (CASE WHEN %s THEN %s ELSE 1/(%s) END) * %s AS rate FROM orderbooks",
+		baseIsSource, rateCalculation, rateCalculation, feeMultiplier(fees, dest.Issuer))
 	query += fmt.Sprintf(" WHERE %s IS NOT NULL", rateCalculation)
 	query += fmt.Sprintf(" ORDER BY orderbooks.title ASC LIMIT %d", queryLimit)
-	return query
+	return query, params
 }
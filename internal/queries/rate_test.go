@@ -0,0 +1,55 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+)
+
+var testSource = Asset{Code: "NGNT", Issuer: "GAWODAROMJ33V5YDFY3NPYTHVYQG7MJXVJ2ND3AOGIHYRWINES6ACCPD"}
+var testDest = Asset{Code: "EURT", Issuer: "GAP5LETOV6YIE62YAM56STDANPRDO7ZFDBGSNHJQIYGGKSMOZAHOOS2S"}
+var testPath = []Asset{testSource, testDest}
+
+// TestCreateRateQueryZeroFeeScheduleReproducesRawRate verifies that a nil or zero-value
+// FeeSchedule leaves the returned rate expression unchanged from the pre-effectiveRate behavior,
+// i.e. it contributes a no-op "* 1" multiplier rather than altering the rate.
+func TestCreateRateQueryZeroFeeScheduleReproducesRawRate(t *testing.T) {
+	for _, fees := range []FeeSchedule{nil, {}, {testDest.Issuer: 0}} {
+		query, _ := createRateQuery(testSource, testDest, "", "", "ledger", fees)
+		if !strings.Contains(query, "END) * 1 AS rate") {
+			t.Errorf("expected zero fee schedule %v to leave the rate unscaled, got query: %s", fees, query)
+		}
+	}
+}
+
+// TestCreateRateQueryAppliesFeeSchedule verifies that a non-zero FeeSchedule entry for the dest
+// issuer scales the returned rate down by the configured bps.
+func TestCreateRateQueryAppliesFeeSchedule(t *testing.T) {
+	fees := FeeSchedule{testDest.Issuer: 25}
+	query, _ := createRateQuery(testSource, testDest, "", "", "ledger", fees)
+	if !strings.Contains(query, "END) * (1 - 25/10000.0) AS rate") {
+		t.Errorf("expected fee schedule to scale the rate by (1 - 25/10000.0), got query: %s", query)
+	}
+}
+
+// TestCreateRateQueryBindsAssetsAsParameters verifies asset codes/issuers are bound as query
+// parameters rather than interpolated into the SQL string.
+func TestCreateRateQueryBindsAssetsAsParameters(t *testing.T) {
+	query, params := createRateQuery(testSource, testDest, "1000", "2000", "ledger", nil)
+	if strings.Contains(query, testSource.Issuer) || strings.Contains(query, testDest.Issuer) {
+		t.Errorf("expected asset issuers to be bound as parameters, not interpolated into the query: %s", query)
+	}
+
+	want := map[string]interface{}{
+		"source_code": testSource.Code, "source_issuer": testSource.Issuer,
+		"dest_code": testDest.Code, "dest_issuer": testDest.Issuer,
+		"start_ts": "1000", "end_ts": "2000",
+	}
+	if len(params) != len(want) {
+		t.Fatalf("expected %d query parameters, got %d: %v", len(want), len(params), params)
+	}
+	for _, p := range params {
+		if want[p.Name] != p.Value {
+			t.Errorf("parameter %s: expected %v, got %v", p.Name, want[p.Name], p.Value)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package queries
+
+import "fmt"
+
+// getTitleField returns the SQL expression (aliased "title") used to group and label each row of
+// a corridor query. aggregateBy of "ledger" formats idField as "Ledger <N>", one row per ledger;
+// "minute", "hour", "day", and "week" instead truncate closedAtField to that calendar unit via
+// TIMESTAMP_TRUNC and format the bucket's start timestamp, collapsing however many ledgers fall in
+// the bucket into a single row. Any other value (including "") falls back to the ledger grouping.
+func getTitleField(idField, closedAtField, aggregateBy string) string {
+	switch aggregateBy {
+	case "minute":
+		return fmt.Sprintf(`FORMAT_TIMESTAMP("%%Y-%%m-%%d %%H:%%M:00", TIMESTAMP_TRUNC(%s, MINUTE)) AS title`, closedAtField)
+	case "hour":
+		return fmt.Sprintf(`FORMAT_TIMESTAMP("%%Y-%%m-%%d %%H:00:00", TIMESTAMP_TRUNC(%s, HOUR)) AS title`, closedAtField)
+	case "day":
+		return fmt.Sprintf(`FORMAT_TIMESTAMP("%%Y-%%m-%%d", TIMESTAMP_TRUNC(%s, DAY)) AS title`, closedAtField)
+	case "week":
+		return fmt.Sprintf(`FORMAT_TIMESTAMP("%%Y-%%m-%%d", TIMESTAMP_TRUNC(%s, WEEK)) AS title`, closedAtField)
+	default:
+		return fmt.Sprintf(`FORMAT("Ledger %%d", %s) AS title`, idField)
+	}
+}
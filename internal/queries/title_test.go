@@ -0,0 +1,32 @@
+package queries
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestGetTitleFieldDefaultsToLedgerGrouping verifies that "ledger" (and any unrecognized value)
+// groups by the raw ledger id rather than truncating the closed_at timestamp.
+func TestGetTitleFieldDefaultsToLedgerGrouping(t *testing.T) {
+	for _, aggregateBy := range []string{"ledger", "", "bogus"} {
+		field := getTitleField("L.sequence", "L.closed_at", aggregateBy)
+		if !strings.Contains(field, `FORMAT("Ledger %d", L.sequence)`) {
+			t.Errorf("aggregateBy %q: expected ledger-grouped title field, got: %s", aggregateBy, field)
+		}
+	}
+}
+
+// TestGetTitleFieldSupportsCalendarBuckets verifies that each calendar aggregateBy value
+// truncates closedAtField to the matching TIMESTAMP_TRUNC unit instead of grouping by ledger.
+func TestGetTitleFieldSupportsCalendarBuckets(t *testing.T) {
+	wantUnit := map[string]string{"minute": "MINUTE", "hour": "HOUR", "day": "DAY", "week": "WEEK"}
+	for aggregateBy, unit := range wantUnit {
+		field := getTitleField("L.sequence", "L.closed_at", aggregateBy)
+		if !strings.Contains(field, "TIMESTAMP_TRUNC(L.closed_at, "+unit+")") {
+			t.Errorf("aggregateBy %q: expected a TIMESTAMP_TRUNC(L.closed_at, %s), got: %s", aggregateBy, unit, field)
+		}
+		if strings.Contains(field, "Ledger") {
+			t.Errorf("aggregateBy %q: expected calendar bucket title field, got ledger grouping: %s", aggregateBy, field)
+		}
+	}
+}